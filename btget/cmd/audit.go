@@ -0,0 +1,68 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/euank/tl/btget/tlog"
+)
+
+// auditCmd prints every entry logged for a URL across the configured logs,
+// letting a user spot an unexpected digest (an attacker-injected binary, or
+// a log that's showing different clients different histories).
+var auditCmd = &cobra.Command{
+	Use:   "audit <url>",
+	Short: "Print the logged history of a URL across the configured logs",
+	Args:  cobra.ExactArgs(1),
+	Run:   audit,
+}
+
+func audit(cmd *cobra.Command, args []string) {
+	durl := args[0]
+
+	t, err := loadTrust()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	digests := map[string]bool{}
+	for _, log := range t.Logs {
+		entries, err := tlog.NewClient(log.URL).GetEntries(durl)
+		if err != nil {
+			fmt.Printf("warning: fetching history from %q: %v\n", log.Description, err)
+			continue
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Entry.Timestamp < entries[j].Entry.Timestamp
+		})
+
+		fmt.Printf("%s (%d entries):\n", log.Description, len(entries))
+		for _, le := range entries {
+			digests[string(le.Entry.SHA256)] = true
+			fmt.Printf("  %d  %x  (%d SCTs)\n", le.Entry.Timestamp, le.Entry.SHA256, len(le.SCTs))
+		}
+	}
+
+	if len(digests) > 1 {
+		fmt.Printf("warning: %d distinct digests have been logged for %s; confirm each resubmission was expected\n", len(digests), durl)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"github.com/euank/tl/btget/download"
+	"github.com/euank/tl/btget/tlog"
+)
+
+// getCmd fetches a URL to a file named after its path, then verifies the
+// file's digest against the transparency log, exiting non-zero if that
+// fails. A file that fails verification is left where it was saved.
+var getCmd = &cobra.Command{
+	Use:   "get <url>",
+	Short: "Fetch a URL and verify it against the transparency log",
+	Args:  cobra.ExactArgs(1),
+	Run:   get,
+}
+
+func get(cmd *cobra.Command, args []string) {
+	durl := args[0]
+
+	t, err := loadTrust()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	dst, err := filenameFor(durl)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	reporter, err := newReporter()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	result, err := download.New().Get(context.Background(), durl, dst, reporter)
+	if err != nil {
+		fmt.Printf("failed to download: %v\n", err)
+		os.Exit(1)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	results, err := tlog.Verify(durl, result.SHA256, t.Logs, t.Policy.Witnesses, dir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if reportEval(t.Policy.Evaluate(results)) {
+		os.Exit(1)
+	}
+
+	fmt.Printf("validated file sum: %x\n", result.SHA256)
+	fmt.Println("Download validated and saved to", result.Filename)
+}
+
+// filenameFor derives a destination filename from durl's path, the same
+// way most URL fetchers pick a default save name.
+func filenameFor(durl string) (string, error) {
+	u, err := url.Parse(durl)
+	if err != nil {
+		return "", fmt.Errorf("parsing %q: %w", durl, err)
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("can't derive a filename from %q", durl)
+	}
+	return name, nil
+}
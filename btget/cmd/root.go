@@ -15,24 +15,39 @@
 package cmd
 
 import (
-	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	"github.com/cavaliercoder/grab"
 	"github.com/google/certificate-transparency-go/loglist"
+
+	"github.com/euank/tl/btget/download"
+	"github.com/euank/tl/btget/tlog"
 )
 
 var cfgFile string
 
-// rootCmd represents the base command when called without any subcommands
+// Shared flags, available to every subcommand via rootCmd's persistent
+// flags.
+var (
+	logNames   []string
+	loglistURL string
+	minSCT     int
+	progress   string
+)
+
+// rootCmd represents the base command when called without any subcommands.
+// For backwards compatibility, calling it with a bare URL and no subcommand
+// is equivalent to `btget get <url>`.
 var rootCmd = &cobra.Command{
 	Use:   "btget [URL]",
 	Short: "Get a URL and verify the contents with a binary tranparency log",
@@ -63,6 +78,15 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.btget.yaml)")
+	rootCmd.PersistentFlags().StringArrayVar(&logNames, "log", nil, "name of a log to use, matched against the descriptions of the logs the configured policy trusts (a pinned log's description is its URL); may be repeated. Defaults to every log the policy trusts")
+	rootCmd.PersistentFlags().StringVar(&loglistURL, "loglist-url", "", "URL of a CT-style log list JSON to resolve the policy's by-name log entries against")
+	rootCmd.PersistentFlags().IntVar(&minSCT, "min-sct", 0, "minimum number of logs that must validate a download or submission, overriding the policy's quorum (0 keeps the policy's quorum)")
+	rootCmd.PersistentFlags().StringVar(&progress, "progress", "bar", "how to report download progress: bar, json, or none")
+
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(submitCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(verifyCmd)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -95,71 +119,171 @@ func initConfig() {
 	}
 }
 
-func validSCTs(valid, invalid int, cturl string, logs []loglist.Log) string {
-	var names []string
-	for _, l := range logs {
-		names = append(names, l.Description)
+// cacheDir returns the directory verified STHs are cached in, creating it if
+// necessary. It lives alongside the config file so `--config` also moves the
+// cache.
+func cacheDir() (string, error) {
+	base := filepath.Dir(cfgFile)
+	if cfgFile == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		base = home
+	}
+	dir := filepath.Join(base, ".btget.d")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("validated %d/%d SCTs in logs %q ", valid, (valid + invalid), strings.Join(names, ", "))
+	return dir, nil
 }
 
-func levelSCTs(valid, invalid int) (string, error) {
-	switch {
-	case valid != 0 && invalid == 0:
-		return "OK", nil
-	case valid == 0:
-		return "Error", errors.New("no valid SCTs")
-	default:
-		return "Warning", nil
+// loadPolicy reads the trust policy from the config file's `policy` key: a
+// quorum, the logs to trust (by name or pinned url/pubkey), and any
+// witnesses that must cosign an STH. For backward compatibility with
+// configs predating policies, a flat `logs` key (a list of loglist.Log, as
+// `btget` originally accepted) is read instead when `policy` isn't set, and
+// treated as a quorum of "all of them".
+//
+// --min-sct, if given, overrides the policy's quorum for this invocation.
+func loadPolicy() (tlog.Policy, error) {
+	var policy tlog.Policy
+	if viper.IsSet("policy") {
+		if err := viper.UnmarshalKey("policy", &policy); err != nil {
+			return tlog.Policy{}, fmt.Errorf("parsing policy: %w", err)
+		}
+	} else {
+		var logs []loglist.Log
+		if err := viper.UnmarshalKey("logs", &logs); err != nil {
+			return tlog.Policy{}, fmt.Errorf("parsing configured logs: %w", err)
+		}
+		for _, l := range logs {
+			policy.Logs = append(policy.Logs, tlog.LogSpec{URL: l.URL, PubKey: l.Key})
+		}
+	}
+	if len(policy.Logs) == 0 {
+		return tlog.Policy{}, errors.New("no logs configured; set `policy.logs` (or the legacy `logs`) in the config file")
 	}
+	if minSCT > 0 {
+		policy.Quorum = minSCT
+	}
+	return policy, nil
 }
 
-func get(cmd *cobra.Command, args []string) {
-	durl := args[0]
+// fetchLogList fetches and parses a CT-style log list JSON document (an
+// object with a top-level "logs" array) from url.
+func fetchLogList(url string) ([]loglist.Log, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching log list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching log list: unexpected status %s", resp.Status)
+	}
+	var list struct {
+		Logs []loglist.Log `json:"logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("parsing log list: %w", err)
+	}
+	return list.Logs, nil
+}
 
-	// Step 1: Download the tlog entry for the URL
+// trust is the resolved set of logs and the policy to judge them by for one
+// command invocation.
+type trust struct {
+	Logs   []loglist.Log
+	Policy tlog.Policy
+}
 
-	// create download request
-	req, err := grab.NewRequest("", durl)
+// loadTrust resolves the policy's LogSpecs into loglist.Logs (fetching
+// --loglist-url if given, to resolve any specs that trust a log by name
+// rather than pinning it directly), then narrows the result to --log names
+// when any are given.
+func loadTrust() (trust, error) {
+	policy, err := loadPolicy()
 	if err != nil {
-		fmt.Printf("failed to create grab request: %v\n", err)
-		os.Exit(1)
+		return trust{}, err
 	}
-	req.NoCreateDirectories = true
 
-	req.AfterCopy = func(resp *grab.Response) (err error) {
-		var f *os.File
-		f, err = os.Open(resp.Filename)
+	var known []loglist.Log
+	if loglistURL != "" {
+		known, err = fetchLogList(loglistURL)
 		if err != nil {
-			return
+			return trust{}, err
 		}
-		defer func() {
-			f.Close()
-		}()
+	}
+	logs, err := policy.ResolveLogs(known)
+	if err != nil {
+		return trust{}, err
+	}
 
-		h := sha256.New()
-		_, err = io.Copy(h, f)
-		if err != nil {
-			return err
+	if len(logNames) > 0 {
+		byName := make(map[string]loglist.Log, len(logs))
+		for _, l := range logs {
+			byName[l.Description] = l
 		}
+		narrowed := make([]loglist.Log, 0, len(logNames))
+		for _, name := range logNames {
+			l, ok := byName[name]
+			if !ok {
+				return trust{}, fmt.Errorf("--log %q is not a log the policy trusts", name)
+			}
+			narrowed = append(narrowed, l)
+		}
+		logs = narrowed
+	}
 
-		fileSum := h.Sum(nil)
-
-		// TODO VALIDATE SUM FILE HERE
-
-		fmt.Printf("validated file sum: %x\n", fileSum)
+	return trust{Logs: logs, Policy: policy}, nil
+}
 
-		req.SetChecksum(sha256.New(), fileSum, true)
+// nowMillis returns the current time as the millisecond Unix timestamp
+// entries and SCTs are stamped with.
+func nowMillis() uint64 {
+	return uint64(time.Now().UnixMilli())
+}
 
-		return
+// newReporter builds the download.Reporter named by --progress.
+func newReporter() (download.Reporter, error) {
+	switch progress {
+	case "bar":
+		return &download.BarReporter{Out: os.Stdout}, nil
+	case "json":
+		return download.JSONReporter{Out: os.Stdout}, nil
+	case "none":
+		return download.NoopReporter{}, nil
+	default:
+		return nil, fmt.Errorf("--progress must be one of bar, json, none; got %q", progress)
 	}
+}
 
-	// download and validate file
-	resp := grab.DefaultClient.Do(req)
-	if err := resp.Err(); err != nil {
-		fmt.Printf("Failed to grab: %v\n", err)
-		os.Exit(1)
+// reportEval prints a human-readable summary of eval (one line per log that
+// didn't validate, then an overall validated-count line and status) and
+// reports whether the caller should treat this as a failure.
+func reportEval(eval tlog.EvalResult) (failed bool) {
+	var names []string
+	valid := 0
+	for _, r := range eval.Outcomes {
+		names = append(names, r.Log.Description)
+		if r.Err != nil {
+			fmt.Printf("warning: %v\n", r.Err)
+		} else {
+			valid++
+		}
 	}
+	fmt.Printf("validated %d/%d SCTs in logs %q ", valid, len(eval.Outcomes), strings.Join(names, ", "))
 
-	fmt.Println("Download validated and saved to", resp.Filename)
+	switch eval.Status {
+	case tlog.EvalOK:
+		fmt.Println("OK")
+	case tlog.EvalWarning:
+		fmt.Println("Warning")
+		fmt.Println("warning:", eval.Reason)
+	case tlog.EvalError:
+		fmt.Println("Error")
+		fmt.Println(eval.Reason)
+		failed = true
+	}
+	return failed
 }
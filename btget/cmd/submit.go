@@ -0,0 +1,92 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/euank/tl/btget/tlog"
+)
+
+// submitCmd submits a file's digest to every configured log and writes the
+// resulting SCTs to a sidecar file, so the submission can later be checked
+// offline with `btget verify`.
+var submitCmd = &cobra.Command{
+	Use:   "submit <url> <file>",
+	Short: "Submit a file's digest to the transparency log and save the resulting SCTs",
+	Args:  cobra.ExactArgs(2),
+	Run:   submit,
+}
+
+func submit(cmd *cobra.Command, args []string) {
+	durl, path := args[0], args[1]
+
+	t, err := loadTrust()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	sum, err := fileSum(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	entry := tlog.Entry{URL: durl, SHA256: sum, Timestamp: nowMillis()}
+
+	sctsByLog := make(map[string][]tlog.SCT, len(t.Logs))
+	results := make([]tlog.LogResult, len(t.Logs))
+	for i, log := range t.Logs {
+		sct, err := tlog.NewClient(log.URL).AddJSON(entry)
+		results[i] = tlog.LogResult{Log: log, Err: err}
+		if err != nil {
+			continue
+		}
+		sctsByLog[log.URL] = []tlog.SCT{sct}
+	}
+
+	if reportEval(t.Policy.Evaluate(results)) {
+		os.Exit(1)
+	}
+
+	sctPath := path + ".sct"
+	if err := tlog.WriteSCTFile(sctPath, tlog.SCTFile{Entry: entry, SCTs: sctsByLog}); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %s\n", sctPath)
+}
+
+// fileSum streams path through SHA-256 without holding the whole file in
+// memory.
+func fileSum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
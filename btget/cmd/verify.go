@@ -0,0 +1,75 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/euank/tl/btget/tlog"
+)
+
+// verifyCmd re-checks a previously downloaded file against SCTs saved by
+// `btget submit`, without needing to fetch the entry from each log again.
+var verifyCmd = &cobra.Command{
+	Use:   "verify <file> <sct-file>",
+	Short: "Verify a file against a sidecar SCT file written by `btget submit`",
+	Args:  cobra.ExactArgs(2),
+	Run:   verify,
+}
+
+func verify(cmd *cobra.Command, args []string) {
+	path, sctPath := args[0], args[1]
+
+	sctFile, err := tlog.ReadSCTFile(sctPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	sum, err := fileSum(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if string(sum) != string(sctFile.Entry.SHA256) {
+		fmt.Printf("%s does not match the digest in %s: got %x, want %x\n", path, sctPath, sum, sctFile.Entry.SHA256)
+		os.Exit(1)
+	}
+
+	t, err := loadTrust()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	results, err := tlog.VerifyOffline(sctFile.Entry, sctFile.SCTs, t.Logs, t.Policy.Witnesses, dir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if reportEval(t.Policy.Evaluate(results)) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// BarReporter prints a single-line, carriage-return-redrawn progress bar to
+// Out as bytes land.
+type BarReporter struct {
+	Out io.Writer
+
+	mu   sync.Mutex
+	size int64
+}
+
+func (b *BarReporter) Start(size int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size = size
+}
+
+func (b *BarReporter) Progress(done int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.size <= 0 {
+		fmt.Fprintf(b.Out, "\r%d bytes", done)
+		return
+	}
+	const width = 30
+	filled := int(float64(width) * float64(done) / float64(b.size))
+	if filled > width {
+		filled = width
+	}
+	fmt.Fprintf(b.Out, "\r[%s%s] %d/%d bytes", strings.Repeat("=", filled), strings.Repeat(" ", width-filled), done, b.size)
+}
+
+func (b *BarReporter) Done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(b.Out, "\nfailed: %v\n", err)
+		return
+	}
+	fmt.Fprintln(b.Out)
+}
@@ -0,0 +1,97 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// chunkRange is a half-open byte range [start, end) of the destination
+// file, fetched with a single range request.
+type chunkRange struct {
+	start, end int64
+}
+
+// planChunks splits size bytes into chunkRanges of at most chunkSize each.
+func planChunks(size, chunkSize int64) []chunkRange {
+	var chunks []chunkRange
+	for start := int64(0); start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size {
+			end = size
+		}
+		chunks = append(chunks, chunkRange{start: start, end: end})
+	}
+	return chunks
+}
+
+// bytesDone sums the size of every chunk marked done.
+func bytesDone(chunks []chunkRange, done []bool) int64 {
+	var n int64
+	for i, c := range chunks {
+		if done[i] {
+			n += c.end - c.start
+		}
+	}
+	return n
+}
+
+// reportDone reports the bytes already completed in st, so resuming a
+// partial download doesn't reset the progress indicator to zero.
+func reportDone(chunks []chunkRange, st *state, r Reporter) {
+	if n := bytesDone(chunks, st.Done); n > 0 {
+		r.Progress(n)
+	}
+}
+
+// state is the resumable progress for a ranged download, persisted as JSON
+// alongside the `.part` file it describes.
+type state struct {
+	Size          int64  `json:"size"`
+	Done          []bool `json:"done"`
+	HashedThrough int64  `json:"hashed_through"`
+	HashState     []byte `json:"hash_state,omitempty"`
+}
+
+// loadState reads a previous state from statePath, discarding it if it
+// doesn't match the transfer being resumed (different size or chunk count,
+// e.g. the server's file changed underneath us).
+func loadState(statePath string, size int64, numChunks int) (*state, error) {
+	fresh := &state{Size: size, Done: make([]bool, numChunks)}
+
+	b, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var prev state
+	if err := json.Unmarshal(b, &prev); err != nil || prev.Size != size || len(prev.Done) != numChunks {
+		return fresh, nil
+	}
+	return &prev, nil
+}
+
+// saveState persists st to statePath.
+func saveState(statePath string, st *state) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, b, 0o600)
+}
@@ -0,0 +1,300 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package download fetches a URL to local storage, hashing it as it streams
+// to disk instead of re-reading the file afterward, and splits the transfer
+// into parallel range requests when the server advertises support for them.
+// A transfer interrupted mid-way through a range-requested download can be
+// resumed by calling Get again with the same destination: both the
+// downloaded bytes and the hash computed over them so far are persisted to a
+// `.part` file and sidecar state.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultChunkSize is the size of each parallel range request: large enough
+// that per-request overhead doesn't dominate, small enough that a resumed
+// download doesn't redo much work.
+const DefaultChunkSize = 8 << 20 // 8MiB
+
+// DefaultConcurrency is the number of chunks fetched in parallel when the
+// server supports range requests.
+const DefaultConcurrency = 4
+
+// Result is the outcome of a completed download.
+type Result struct {
+	Filename string
+	SHA256   []byte
+	Size     int64
+}
+
+// Downloader fetches URLs to local storage.
+type Downloader struct {
+	HTTP        *http.Client
+	ChunkSize   int64
+	Concurrency int
+}
+
+// New returns a Downloader with the package's default chunk size and
+// concurrency.
+func New() *Downloader {
+	return &Downloader{
+		HTTP:        http.DefaultClient,
+		ChunkSize:   DefaultChunkSize,
+		Concurrency: DefaultConcurrency,
+	}
+}
+
+// Get downloads durl to dst, reporting progress to r (pass NoopReporter{} to
+// discard it). If the server reports a size and supports range requests,
+// the transfer is split into parallel chunks and made resumable: while in
+// progress it lives at dst+".part" plus a ".state" sidecar recording which
+// chunks have landed and the SHA-256 state over the longest hashed prefix,
+// and calling Get again with the same dst resumes rather than restarting.
+// Otherwise, it falls back to a single streaming request that isn't
+// resumable.
+func (d *Downloader) Get(ctx context.Context, durl, dst string, r Reporter) (Result, error) {
+	if r == nil {
+		r = NoopReporter{}
+	}
+
+	size, canRange, err := d.probe(ctx, durl)
+	if err != nil {
+		return Result{}, fmt.Errorf("probing %s: %w", durl, err)
+	}
+	if size <= 0 || !canRange {
+		return d.getStream(ctx, durl, dst, r)
+	}
+	return d.getRanged(ctx, durl, dst, size, r)
+}
+
+// probe checks whether durl supports range requests and, if the server
+// reports one, its Content-Length.
+func (d *Downloader) probe(ctx context.Context, durl string) (size int64, canRange bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, durl, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := d.HTTP.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.ContentLength, resp.Header.Get("Accept-Ranges") == "bytes", nil
+}
+
+// getStream fetches durl with a single, non-resumable request, hashing the
+// body with an io.TeeReader as it's written to disk.
+func (d *Downloader) getStream(ctx context.Context, durl, dst string, r Reporter) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, durl, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	resp, err := d.HTTP.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	f, err := os.Create(dst + ".part")
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	size := resp.ContentLength
+	if size < 0 {
+		size = 0 // unknown
+	}
+	r.Start(size)
+	h := sha256.New()
+	n, err := io.Copy(f, &progressReader{r: io.TeeReader(resp.Body, h), reporter: r})
+	if err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+	if err := f.Close(); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+	if err := os.Rename(dst+".part", dst); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+
+	r.Done(nil)
+	return Result{Filename: dst, SHA256: h.Sum(nil), Size: n}, nil
+}
+
+// getRanged fetches durl's size bytes in parallel chunks, resuming from any
+// `.part`/state left by a previous interrupted attempt.
+func (d *Downloader) getRanged(ctx context.Context, durl, dst string, size int64, r Reporter) (Result, error) {
+	partPath := dst + ".part"
+	statePath := partPath + ".state"
+
+	chunks := planChunks(size, d.ChunkSize)
+	st, err := loadState(statePath, size, len(chunks))
+	if err != nil {
+		return Result{}, err
+	}
+
+	f, err := os.OpenFile(partPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		return Result{}, err
+	}
+
+	r.Start(size)
+	reportDone(chunks, st, r)
+
+	h := newHasher(st.HashState)
+	if b, err := h.MarshalBinary(); err != nil || string(b) != string(st.HashState) {
+		// newHasher fell back to a fresh hash, so rehash the done chunks
+		// already on disk from the start.
+		st.HashedThrough = 0
+	}
+	if err := advanceHash(f, chunks, st, h, statePath); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+	if err := d.fetchChunks(ctx, durl, f, chunks, st, h, statePath, r); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+
+	sum := h.Sum(nil)
+	if err := f.Close(); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+	if err := os.Rename(partPath, dst); err != nil {
+		r.Done(err)
+		return Result{}, err
+	}
+	os.Remove(statePath)
+
+	r.Done(nil)
+	return Result{Filename: dst, SHA256: sum, Size: size}, nil
+}
+
+// fetchChunks downloads every not-yet-done chunk, up to Concurrency at a
+// time, writing each directly to its offset in f. After every chunk
+// completes, it advances the hash over any now-contiguous completed prefix
+// and persists st, so a crash doesn't lose hashing progress either.
+func (d *Downloader) fetchChunks(ctx context.Context, durl string, f *os.File, chunks []chunkRange, st *state, h hasher, statePath string, r Reporter) error {
+	sem := make(chan struct{}, max(d.Concurrency, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for i, c := range chunks {
+		if st.Done[i] {
+			continue
+		}
+		i, c := i, c
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fetchErr := d.fetchChunk(ctx, durl, f, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if fetchErr != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("fetching bytes %d-%d: %w", c.start, c.end, fetchErr)
+				}
+				return
+			}
+			st.Done[i] = true
+			r.Progress(bytesDone(chunks, st.Done))
+			if err := advanceHash(f, chunks, st, h, statePath); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// fetchChunk issues a single range request and streams the response
+// directly to c's offset in f.
+func (d *Downloader) fetchChunk(ctx context.Context, durl string, f *os.File, c chunkRange) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, durl, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", c.start, c.end-1))
+	resp, err := d.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// A server or intermediate proxy that ignores Range is allowed to
+	// answer with a plain 200 and the full body; accepting that here would
+	// write the whole response at this chunk's offset, corrupting it and
+	// every chunk it overlaps, and since the caller marks the chunk done
+	// right after, the corruption would survive a resume too.
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("unexpected status %s (server may be ignoring Range requests)", resp.Status)
+	}
+	wantRange := fmt.Sprintf("bytes %d-%d/", c.start, c.end-1)
+	if cr := resp.Header.Get("Content-Range"); !strings.HasPrefix(cr, wantRange) {
+		return fmt.Errorf("response Content-Range %q does not match requested %q", cr, wantRange)
+	}
+	_, err = io.Copy(io.NewOffsetWriter(f, c.start), resp.Body)
+	return err
+}
+
+// progressReader wraps r, reporting the running total of bytes read to
+// reporter after each Read.
+type progressReader struct {
+	r        io.Reader
+	reporter Reporter
+	total    int64
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.total += int64(n)
+	p.reporter.Progress(p.total)
+	return n, err
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"hash"
+	"io"
+	"os"
+)
+
+// hasher is the subset of hash.Hash this package needs, plus the
+// BinaryMarshaler every stdlib hash.Hash already implements, used to
+// persist hashing progress across resumed downloads.
+type hasher interface {
+	hash.Hash
+	encoding.BinaryMarshaler
+}
+
+// newHasher returns a SHA-256 hasher, restoring previously marshaled state
+// if any is given. Unrecognized state is treated as absent rather than
+// failing the download, since it only costs a rehash of the bytes already
+// on disk.
+func newHasher(state []byte) hasher {
+	h := sha256.New().(hasher)
+	if len(state) == 0 {
+		return h
+	}
+	if u, ok := h.(encoding.BinaryUnmarshaler); ok {
+		if err := u.UnmarshalBinary(state); err == nil {
+			return h
+		}
+	}
+	return sha256.New().(hasher)
+}
+
+// advanceHash hashes every chunk of f that's both done and contiguous with
+// the hash's current position, so the final digest never requires
+// rereading the whole file. It then persists st, including any chunks marked
+// done beyond that prefix, so the hashing progress survives a crash along
+// with the chunks themselves.
+func advanceHash(f *os.File, chunks []chunkRange, st *state, h hasher, statePath string) error {
+	advanced := false
+	for {
+		i := chunkAt(chunks, st.HashedThrough)
+		if i < 0 || !st.Done[i] {
+			break
+		}
+		c := chunks[i]
+		if _, err := io.Copy(h, io.NewSectionReader(f, c.start, c.end-c.start)); err != nil {
+			return err
+		}
+		st.HashedThrough = c.end
+		advanced = true
+	}
+	if advanced {
+		b, err := h.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		st.HashState = b
+	}
+	return saveState(statePath, st)
+}
+
+// chunkAt returns the index of the chunk starting at offset, or -1.
+func chunkAt(chunks []chunkRange, offset int64) int {
+	for i, c := range chunks {
+		if c.start == offset {
+			return i
+		}
+	}
+	return -1
+}
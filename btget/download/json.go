@@ -0,0 +1,59 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONReporter writes one JSON object per line to Out for each progress
+// update, so a CI job can track a download without parsing a human-format
+// progress bar.
+type JSONReporter struct {
+	Out io.Writer
+}
+
+type jsonEvent struct {
+	Event string `json:"event"`
+	Size  int64  `json:"size,omitempty"`
+	Done  int64  `json:"done,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (j JSONReporter) Start(size int64) {
+	j.emit(jsonEvent{Event: "start", Size: size})
+}
+
+func (j JSONReporter) Progress(done int64) {
+	j.emit(jsonEvent{Event: "progress", Done: done})
+}
+
+func (j JSONReporter) Done(err error) {
+	ev := jsonEvent{Event: "done"}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	j.emit(ev)
+}
+
+func (j JSONReporter) emit(ev jsonEvent) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+	j.Out.Write(b)
+}
@@ -0,0 +1,39 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+// Reporter receives progress updates from a Downloader. Start is called
+// once, when the transfer begins, with the total size in bytes or 0 if it
+// isn't known. Progress is called as bytes land, with the cumulative total
+// completed so far (not a delta). Done is called exactly once after Start,
+// with the error the download failed with, if any. A download that fails
+// before the transfer begins (e.g. while probing the URL) calls neither
+// Start nor Done.
+//
+// Progress may be called from more than one goroutine when a ranged
+// download has more than one chunk in flight, though the Downloader never
+// makes two calls to it at the same time.
+type Reporter interface {
+	Start(size int64)
+	Progress(done int64)
+	Done(err error)
+}
+
+// NoopReporter discards all progress updates.
+type NoopReporter struct{}
+
+func (NoopReporter) Start(int64)    {}
+func (NoopReporter) Progress(int64) {}
+func (NoopReporter) Done(error)     {}
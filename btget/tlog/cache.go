@@ -0,0 +1,68 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const sthCacheFile = "sth_cache.json"
+
+// STHCache persists the most recent verified STH seen for each log, keyed by
+// log URL, so that future runs can check consistency against it rather than
+// trusting whatever STH the log hands back next.
+type STHCache struct {
+	path    string
+	entries map[string]STH
+}
+
+// OpenSTHCache loads the cache from dir, creating an empty one if none
+// exists yet.
+func OpenSTHCache(dir string) (*STHCache, error) {
+	c := &STHCache{
+		path:    filepath.Join(dir, sthCacheFile),
+		entries: map[string]STH{},
+	}
+	b, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get returns the last verified STH cached for logURL, if any.
+func (c *STHCache) Get(logURL string) (STH, bool) {
+	sth, ok := c.entries[logURL]
+	return sth, ok
+}
+
+// Put records sth as the latest verified STH for logURL and persists the
+// cache to disk.
+func (c *STHCache) Put(logURL string, sth STH) error {
+	c.entries[logURL] = sth
+	b, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b, 0o600)
+}
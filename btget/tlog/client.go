@@ -0,0 +1,174 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Client talks to a single log's HTTP API. The API shape mirrors RFC 6962's
+// (get-sth, get-sth-consistency, get-proof-by-hash), with get-entry and a
+// URL-keyed get-entries for lookups and add-json for submissions, since logs
+// here serve arbitrary {url, sha256} entries rather than X.509 certificates.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient returns a Client for the log reachable at baseURL, e.g.
+// "https://ct.example.com/log".
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Client) get(path string, query url.Values, out interface{}) error {
+	u := fmt.Sprintf("%s/%s", c.BaseURL, path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	resp, err := c.HTTP.Get(u)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// GetEntry fetches the transparency entry and accompanying SCTs a log holds
+// for durl, i.e. what a prior `btget submit` call received back.
+func (c *Client) GetEntry(durl string) (Entry, []SCT, error) {
+	var resp struct {
+		Entry Entry `json:"entry"`
+		SCTs  []SCT `json:"scts"`
+	}
+	q := url.Values{"url": []string{durl}}
+	if err := c.get("ct/v1/get-entry", q, &resp); err != nil {
+		return Entry{}, nil, err
+	}
+	return resp.Entry, resp.SCTs, nil
+}
+
+// LoggedEntry pairs an Entry with the SCTs a log issued for it, as returned
+// by get-entries.
+type LoggedEntry struct {
+	Entry Entry `json:"entry"`
+	SCTs  []SCT `json:"scts"`
+}
+
+// GetEntries fetches every entry a log holds for durl, in the order the log
+// returns them; it does not sort them. A URL
+// can legitimately be resubmitted (e.g. a new release), but an unexpected
+// digest appearing in the history, or an entry appearing in one log and not
+// another, is a sign the log itself or the download is compromised.
+func (c *Client) GetEntries(durl string) ([]LoggedEntry, error) {
+	var resp struct {
+		Entries []LoggedEntry `json:"entries"`
+	}
+	q := url.Values{"url": []string{durl}}
+	if err := c.get("ct/v1/get-entries", q, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
+
+// GetSTH fetches the log's current signed tree head.
+func (c *Client) GetSTH() (STH, error) {
+	var sth STH
+	err := c.get("ct/v1/get-sth", nil, &sth)
+	return sth, err
+}
+
+// GetProofByHash fetches the Merkle audit path proving that leafHash is
+// present in the tree of the given size.
+func (c *Client) GetProofByHash(leafHash []byte, treeSize int64) (index int64, auditPath [][]byte, err error) {
+	var resp struct {
+		LeafIndex int64    `json:"leaf_index"`
+		AuditPath []string `json:"audit_path"`
+	}
+	q := url.Values{
+		"hash":      []string{base64.StdEncoding.EncodeToString(leafHash)},
+		"tree_size": []string{strconv.FormatInt(treeSize, 10)},
+	}
+	if err := c.get("ct/v1/get-proof-by-hash", q, &resp); err != nil {
+		return 0, nil, err
+	}
+	auditPath = make([][]byte, len(resp.AuditPath))
+	for i, b64 := range resp.AuditPath {
+		b, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("decoding audit path entry %d: %w", i, err)
+		}
+		auditPath[i] = b
+	}
+	return resp.LeafIndex, auditPath, nil
+}
+
+// GetSTHConsistency fetches a consistency proof between two tree sizes a log
+// has previously reported, letting a client confirm the log hasn't
+// rewritten history between runs.
+func (c *Client) GetSTHConsistency(first, second int64) ([][]byte, error) {
+	var resp struct {
+		Consistency []string `json:"consistency"`
+	}
+	q := url.Values{
+		"first":  []string{strconv.FormatInt(first, 10)},
+		"second": []string{strconv.FormatInt(second, 10)},
+	}
+	if err := c.get("ct/v1/get-sth-consistency", q, &resp); err != nil {
+		return nil, err
+	}
+	proof := make([][]byte, len(resp.Consistency))
+	for i, b64 := range resp.Consistency {
+		b, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("decoding consistency proof entry %d: %w", i, err)
+		}
+		proof[i] = b
+	}
+	return proof, nil
+}
+
+// AddJSON submits entry to the log for inclusion and returns the SCT it is
+// issued in response.
+func (c *Client) AddJSON(entry Entry) (SCT, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return SCT{}, err
+	}
+	resp, err := c.HTTP.Post(c.BaseURL+"/ct/v1/add-json", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return SCT{}, fmt.Errorf("POST add-json: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return SCT{}, fmt.Errorf("POST add-json: unexpected status %s", resp.Status)
+	}
+	var sct SCT
+	err = json.NewDecoder(resp.Body).Decode(&sct)
+	return sct, err
+}
@@ -0,0 +1,60 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tlog implements client-side verification of entries submitted to a
+// binary transparency log: an append-only Merkle tree, served over the same
+// wire protocol as Certificate Transparency (RFC 6962), that binds a URL to
+// the SHA-256 digest of the bytes served at that URL.
+package tlog
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+)
+
+// Entry is the statement a log leaf commits to: "the file at URL has this
+// SHA256 digest, as of Timestamp". It is the generic, non-X.509 analogue of
+// the certificate a CT log would otherwise commit to.
+type Entry struct {
+	URL       string `json:"url"`
+	SHA256    []byte `json:"sha256"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// LeafData returns the canonical byte encoding of e that is hashed into the
+// Merkle tree leaf. It is deliberately simple (fixed-width timestamp
+// followed by the JSON statement) rather than reusing RFC 6962's
+// TimestampedEntry, since entries here never contain an X.509 certificate.
+func (e Entry) LeafData() ([]byte, error) {
+	statement, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 8, 8+len(statement))
+	binary.BigEndian.PutUint64(buf, e.Timestamp)
+	return append(buf, statement...), nil
+}
+
+// LeafHash returns the RFC 6962 leaf hash (SHA-256 of a 0x00 leaf prefix
+// followed by the leaf data) used to look an entry up in the tree via
+// get-proof-by-hash and to verify inclusion proofs.
+func (e Entry) LeafHash() ([]byte, error) {
+	data, err := e.LeafData()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(append([]byte{leafHashPrefix}, data...))
+	return h[:], nil
+}
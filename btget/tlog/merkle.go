@@ -0,0 +1,140 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// RFC 6962 section 2.1 hash prefixes, used to domain-separate leaf hashes
+// from interior node hashes so that a proof cannot be confused for a
+// (shorter) subtree's leaf, or vice versa.
+const (
+	leafHashPrefix = 0x00
+	nodeHashPrefix = 0x01
+)
+
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodeHashPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// VerifyInclusion recomputes a Merkle tree head from leafHash, its index and
+// the audit path returned by get-proof-by-hash, and reports whether it
+// matches root. The algorithm is RFC 6962 section 2.1.1's audit path
+// verification.
+func VerifyInclusion(leafHash []byte, index, treeSize int64, auditPath [][]byte, root []byte) error {
+	if index < 0 || index >= treeSize {
+		return fmt.Errorf("leaf index %d out of range for tree size %d", index, treeSize)
+	}
+
+	node, last := index, treeSize-1
+	hash := leafHash
+	for _, sibling := range auditPath {
+		if last == 0 {
+			return fmt.Errorf("audit path too long: %d entries left over", len(auditPath))
+		}
+		if node%2 == 1 || node == last {
+			hash = hashChildren(sibling, hash)
+			for node%2 == 0 && node != 0 {
+				node /= 2
+				last /= 2
+			}
+		} else {
+			hash = hashChildren(hash, sibling)
+		}
+		node /= 2
+		last /= 2
+	}
+	if last != 0 {
+		return fmt.Errorf("audit path too short: stopped above the root")
+	}
+	if string(hash) != string(root) {
+		return fmt.Errorf("recomputed root does not match signed tree head")
+	}
+	return nil
+}
+
+// VerifyConsistency checks that proof demonstrates new is a valid append-only
+// evolution of old: every leaf committed to by old's root hash is still
+// present, in the same order, under new's root hash.
+func VerifyConsistency(old, latest STH, proof [][]byte) error {
+	if old.TreeSize == 0 {
+		// Any tree is trivially consistent with the empty tree.
+		return nil
+	}
+	if old.TreeSize == latest.TreeSize {
+		if len(proof) != 0 {
+			return fmt.Errorf("unexpected non-empty consistency proof for equal tree sizes")
+		}
+		if string(old.RootHash) != string(latest.RootHash) {
+			return fmt.Errorf("root hash changed at a fixed tree size")
+		}
+		return nil
+	}
+	if len(proof) == 0 {
+		return fmt.Errorf("empty consistency proof")
+	}
+
+	node, last := old.TreeSize-1, latest.TreeSize-1
+	for node%2 == 1 {
+		node /= 2
+		last /= 2
+	}
+
+	var rest [][]byte
+	var oldHash, newHash []byte
+	if node > 0 {
+		oldHash, newHash = proof[0], proof[0]
+		rest = proof[1:]
+	} else {
+		// old's tree is fully contained in new's left subtree.
+		oldHash, newHash = old.RootHash, old.RootHash
+		rest = proof
+	}
+
+	for _, sibling := range rest {
+		if last == 0 {
+			return fmt.Errorf("consistency proof too long")
+		}
+		if node%2 == 1 || node == last {
+			newHash = hashChildren(sibling, newHash)
+			oldHash = hashChildren(sibling, oldHash)
+			for node%2 == 0 && node != 0 {
+				node /= 2
+				last /= 2
+			}
+		} else {
+			newHash = hashChildren(newHash, sibling)
+		}
+		node /= 2
+		last /= 2
+	}
+
+	if string(oldHash) != string(old.RootHash) {
+		return fmt.Errorf("consistency proof does not reproduce the earlier root hash")
+	}
+	if last != 0 {
+		return fmt.Errorf("consistency proof too short")
+	}
+	if string(newHash) != string(latest.RootHash) {
+		return fmt.Errorf("consistency proof does not reproduce the current root hash")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+// The following functions are a direct transcription of the reference
+// algorithms in RFC 6962 §2.1.1 (MTH, PATH) and §2.1.2 (SUBPROOF), used only
+// to build fixtures for the tests below; VerifyInclusion/VerifyConsistency
+// must independently agree with them.
+
+func testLeaf(i int) []byte {
+	return []byte(fmt.Sprintf("leaf-%d", i))
+}
+
+func mth(leaves [][]byte) []byte {
+	n := len(leaves)
+	if n == 0 {
+		h := sha256.Sum256(nil)
+		return h[:]
+	}
+	if n == 1 {
+		h := sha256.Sum256(append([]byte{leafHashPrefix}, leaves[0]...))
+		return h[:]
+	}
+	k := largestPowerOfTwoBelow(n)
+	return hashChildren(mth(leaves[:k]), mth(leaves[k:]))
+}
+
+func path(m int, leaves [][]byte) [][]byte {
+	n := len(leaves)
+	if n == 1 {
+		return nil
+	}
+	k := largestPowerOfTwoBelow(n)
+	if m < k {
+		return append(path(m, leaves[:k]), mth(leaves[k:]))
+	}
+	return append(path(m-k, leaves[k:]), mth(leaves[:k]))
+}
+
+func consistencyProof(m int, leaves [][]byte) [][]byte {
+	n := len(leaves)
+	if m == n {
+		return nil
+	}
+	return subproof(m, leaves, true)
+}
+
+func subproof(m int, leaves [][]byte, b bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if b {
+			return nil
+		}
+		return [][]byte{mth(leaves)}
+	}
+	k := largestPowerOfTwoBelow(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], b), mth(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), mth(leaves[:k]))
+}
+
+func largestPowerOfTwoBelow(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func leavesUpTo(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = testLeaf(i)
+	}
+	return leaves
+}
+
+func leafHashOf(i int) []byte {
+	h := sha256.Sum256(append([]byte{leafHashPrefix}, testLeaf(i)...))
+	return h[:]
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	const size = 7
+	leaves := leavesUpTo(size)
+	root := mth(leaves)
+
+	for index := 0; index < size; index++ {
+		index := index
+		t.Run(fmt.Sprintf("leaf-%d", index), func(t *testing.T) {
+			proof := path(index, leaves)
+			if err := VerifyInclusion(leafHashOf(index), int64(index), size, proof, root); err != nil {
+				t.Fatalf("valid proof rejected: %v", err)
+			}
+		})
+	}
+
+	proof := path(3, leaves)
+
+	if err := VerifyInclusion(leafHashOf(3), 2, size, proof, root); err == nil {
+		t.Error("expected error for a proof checked against the wrong index")
+	}
+	if err := VerifyInclusion(leafHashOf(4), 3, size, proof, root); err == nil {
+		t.Error("expected error for the wrong leaf hash")
+	}
+	tamperedRoot := append([]byte{}, root...)
+	tamperedRoot[0] ^= 0xff
+	if err := VerifyInclusion(leafHashOf(3), 3, size, proof, tamperedRoot); err == nil {
+		t.Error("expected error for a tampered root hash")
+	}
+	if err := VerifyInclusion(leafHashOf(3), -1, size, proof, root); err == nil {
+		t.Error("expected error for a negative index")
+	}
+	if err := VerifyInclusion(leafHashOf(3), size, size, proof, root); err == nil {
+		t.Error("expected error for an index equal to the tree size")
+	}
+}
+
+func TestVerifyConsistency(t *testing.T) {
+	leaves := leavesUpTo(8)
+
+	for oldSize := 1; oldSize <= 8; oldSize++ {
+		for newSize := oldSize; newSize <= 8; newSize++ {
+			oldSize, newSize := oldSize, newSize
+			t.Run(fmt.Sprintf("%d-to-%d", oldSize, newSize), func(t *testing.T) {
+				old := STH{TreeSize: int64(oldSize), RootHash: mth(leaves[:oldSize])}
+				latest := STH{TreeSize: int64(newSize), RootHash: mth(leaves[:newSize])}
+				proof := consistencyProof(oldSize, leaves[:newSize])
+				if err := VerifyConsistency(old, latest, proof); err != nil {
+					t.Fatalf("valid consistency proof rejected: %v", err)
+				}
+			})
+		}
+	}
+
+	old := STH{TreeSize: 0}
+	latest := STH{TreeSize: 8, RootHash: mth(leaves[:8])}
+	if err := VerifyConsistency(old, latest, nil); err != nil {
+		t.Errorf("expected any tree to be consistent with the empty tree: %v", err)
+	}
+
+	// A log that reports the same tree size across two runs but signs a
+	// different root hash (a fixed-size split view, or a stale-tree replay)
+	// must be rejected even though no tree-size change, and hence no
+	// consistency proof, is in play.
+	oldFixed := STH{TreeSize: 5, RootHash: mth(leaves[:5])}
+	rewritten := STH{TreeSize: 5, RootHash: mth(append(append([][]byte{}, leaves[:4]...), testLeaf(99)))}
+	if err := VerifyConsistency(oldFixed, rewritten, nil); err == nil {
+		t.Error("expected error for a different root hash at a fixed tree size")
+	}
+
+	oldSize, newSize := 3, 8
+	proof := consistencyProof(oldSize, leaves[:newSize])
+	oldSTH := STH{TreeSize: int64(oldSize), RootHash: mth(leaves[:oldSize])}
+	goodLatest := STH{TreeSize: int64(newSize), RootHash: mth(leaves[:newSize])}
+
+	tamperedLatest := goodLatest
+	tamperedLatest.RootHash = append([]byte{}, goodLatest.RootHash...)
+	tamperedLatest.RootHash[0] ^= 0xff
+	if err := VerifyConsistency(oldSTH, tamperedLatest, proof); err == nil {
+		t.Error("expected error for a tampered latest root hash")
+	}
+
+	tamperedOld := oldSTH
+	tamperedOld.RootHash = append([]byte{}, oldSTH.RootHash...)
+	tamperedOld.RootHash[0] ^= 0xff
+	if err := VerifyConsistency(tamperedOld, goodLatest, proof); err == nil {
+		t.Error("expected error for a tampered old root hash")
+	}
+
+	if err := VerifyConsistency(oldSTH, goodLatest, nil); err == nil {
+		t.Error("expected error for an empty consistency proof when sizes differ")
+	}
+	if len(proof) > 0 {
+		if err := VerifyConsistency(oldSTH, goodLatest, proof[:len(proof)-1]); err == nil {
+			t.Error("expected error for a truncated consistency proof")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"fmt"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+// LogSpec names a log a Policy trusts, either by looking it up in a known
+// log list (Name) or by pinning it directly (URL and PubKey), for logs an
+// operator trusts but that aren't in any published list.
+type LogSpec struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url,omitempty"`
+	PubKey []byte `json:"pubkey,omitempty"`
+}
+
+// Witness is a split-view witness: a third party that cosigns an STH to
+// attest it saw the same tree head other clients did, so a single
+// compromised or misbehaving log can't show different clients different
+// histories without the collusion being detectable. This plays the same
+// role TUF's threshold signatures play for a repository.
+type Witness struct {
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Key         []byte `json:"key"`
+}
+
+// Policy is an operator's trust configuration: which logs to require SCTs
+// from, how many of them must agree, and which witnesses must cosign the
+// STH an inclusion proof is checked against.
+type Policy struct {
+	Logs      []LogSpec `json:"logs"`
+	Quorum    int       `json:"quorum"`
+	Witnesses []Witness `json:"witnesses,omitempty"`
+}
+
+// ResolveLogs turns p's LogSpecs into loglist.Logs, looking Name specs up in
+// known (matched by Description), and building pinned entries directly from
+// URL/PubKey specs.
+func (p Policy) ResolveLogs(known []loglist.Log) ([]loglist.Log, error) {
+	byName := make(map[string]loglist.Log, len(known))
+	for _, l := range known {
+		byName[l.Description] = l
+	}
+
+	logs := make([]loglist.Log, 0, len(p.Logs))
+	for _, spec := range p.Logs {
+		switch {
+		case spec.Name != "":
+			l, ok := byName[spec.Name]
+			if !ok {
+				return nil, fmt.Errorf("policy: log %q not found in the known log list", spec.Name)
+			}
+			logs = append(logs, l)
+		case spec.URL != "" && len(spec.PubKey) > 0:
+			logs = append(logs, loglist.Log{Description: spec.URL, URL: spec.URL, Key: spec.PubKey})
+		default:
+			return nil, fmt.Errorf("policy: log entry needs either a name or a url and pubkey")
+		}
+	}
+	return logs, nil
+}
+
+// quorum returns the number of logs, out of total, this policy requires to
+// validate, defaulting to requiring all of them.
+func (p Policy) quorum(total int) int {
+	if p.Quorum > 0 {
+		return p.Quorum
+	}
+	return total
+}
+
+// EvalStatus is the overall verdict Policy.Evaluate reaches for a set of
+// LogResults.
+type EvalStatus string
+
+const (
+	// EvalOK means every configured log validated.
+	EvalOK EvalStatus = "ok"
+	// EvalWarning means the quorum validated, but not every configured log did.
+	EvalWarning EvalStatus = "warning"
+	// EvalError means fewer logs validated than the quorum requires.
+	EvalError EvalStatus = "error"
+)
+
+// EvalResult is the structured outcome of evaluating a policy against a set
+// of per-log verification results.
+type EvalResult struct {
+	Status   EvalStatus
+	Reason   string
+	Outcomes []LogResult
+}
+
+// Evaluate judges results (one entry per log Policy trusts, in the same
+// order) against the policy's quorum, replacing the old pass/warn/fail
+// string levels with a structured verdict callers can inspect per log.
+func (p Policy) Evaluate(results []LogResult) EvalResult {
+	valid := 0
+	for _, r := range results {
+		if r.Err == nil {
+			valid++
+		}
+	}
+
+	required := p.quorum(len(results))
+	switch {
+	case valid < required:
+		return EvalResult{
+			Status:   EvalError,
+			Reason:   fmt.Sprintf("only %d/%d logs validated, need %d", valid, len(results), required),
+			Outcomes: results,
+		}
+	case valid < len(results):
+		return EvalResult{
+			Status:   EvalWarning,
+			Reason:   "not all configured logs validated",
+			Outcomes: results,
+		}
+	default:
+		return EvalResult{Status: EvalOK, Outcomes: results}
+	}
+}
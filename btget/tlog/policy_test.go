@@ -0,0 +1,124 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+func TestPolicyResolveLogs(t *testing.T) {
+	known := []loglist.Log{
+		{Description: "Log A", URL: "https://a.example/log"},
+		{Description: "Log B", URL: "https://b.example/log"},
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		p := Policy{Logs: []LogSpec{{Name: "Log B"}}}
+		logs, err := p.ResolveLogs(known)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(logs) != 1 || logs[0].Description != "Log B" {
+			t.Fatalf("got %+v, want Log B", logs)
+		}
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		p := Policy{Logs: []LogSpec{{Name: "Log Z"}}}
+		if _, err := p.ResolveLogs(known); err == nil {
+			t.Fatal("expected error for a name not in the known log list")
+		}
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		p := Policy{Logs: []LogSpec{{URL: "https://c.example/log", PubKey: []byte("key")}}}
+		logs, err := p.ResolveLogs(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(logs) != 1 || logs[0].URL != "https://c.example/log" || string(logs[0].Key) != "key" {
+			t.Fatalf("got %+v, want a pinned entry for c.example", logs)
+		}
+	})
+
+	t.Run("incomplete spec", func(t *testing.T) {
+		p := Policy{Logs: []LogSpec{{URL: "https://c.example/log"}}}
+		if _, err := p.ResolveLogs(nil); err == nil {
+			t.Fatal("expected error for a url with no pubkey and no name")
+		}
+	})
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	logA := loglist.Log{Description: "Log A"}
+	logB := loglist.Log{Description: "Log B"}
+	logC := loglist.Log{Description: "Log C"}
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		quorum  int
+		results []LogResult
+		want    EvalStatus
+	}{
+		{
+			name:    "all valid",
+			results: []LogResult{{Log: logA}, {Log: logB}, {Log: logC}},
+			want:    EvalOK,
+		},
+		{
+			name:    "one failed, default quorum is all",
+			results: []LogResult{{Log: logA}, {Log: logB, Err: errFailed}, {Log: logC}},
+			want:    EvalError,
+		},
+		{
+			name:    "one failed, quorum allows it",
+			quorum:  2,
+			results: []LogResult{{Log: logA}, {Log: logB, Err: errFailed}, {Log: logC}},
+			want:    EvalWarning,
+		},
+		{
+			name:    "exactly at quorum",
+			quorum:  2,
+			results: []LogResult{{Log: logA}, {Log: logB}, {Log: logC, Err: errFailed}},
+			want:    EvalWarning,
+		},
+		{
+			name:    "below quorum",
+			quorum:  2,
+			results: []LogResult{{Log: logA}, {Log: logB, Err: errFailed}, {Log: logC, Err: errFailed}},
+			want:    EvalError,
+		},
+		{
+			name:    "quorum equal to all logs",
+			quorum:  3,
+			results: []LogResult{{Log: logA}, {Log: logB}, {Log: logC}},
+			want:    EvalOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			eval := Policy{Quorum: tt.quorum}.Evaluate(tt.results)
+			if eval.Status != tt.want {
+				t.Errorf("got status %v, want %v (reason: %s)", eval.Status, tt.want, eval.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+// SCT is a Signed Certificate Timestamp, repurposed here as a log's promise
+// to merge an Entry within its maximum merge delay. It mirrors the RFC 6962
+// §3.2 wire format but signs over an Entry instead of a TimestampedEntry
+// carrying an X.509 certificate.
+type SCT struct {
+	LogID     []byte `json:"log_id"`
+	Timestamp uint64 `json:"timestamp"`
+	Signature []byte `json:"signature"`
+}
+
+// signedData returns the bytes an SCT's Signature is computed over: the
+// timestamp followed by the entry's leaf data, matching the input a log
+// must hash when it countersigns a submission.
+func (s SCT) signedData(e Entry) ([]byte, error) {
+	leaf, err := e.LeafData()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 8, 8+len(leaf))
+	binary.BigEndian.PutUint64(buf, s.Timestamp)
+	return append(buf, leaf...), nil
+}
+
+// VerifySCT checks that sct is a valid signature by log over entry: the
+// timestamp and leaf data sign-verify under the log's public key, and the
+// SCT's LogID matches the log's key ID (SHA-256 of its public key, per RFC
+// 6962 §3.2).
+func VerifySCT(log loglist.Log, entry Entry, sct SCT) error {
+	pub, err := parsePublicKey(log.Key)
+	if err != nil {
+		return fmt.Errorf("parsing public key for log %q: %w", log.Description, err)
+	}
+
+	logID := sha256.Sum256(keyDER(log.Key))
+	if string(logID[:]) != string(sct.LogID) {
+		return fmt.Errorf("SCT log ID does not match log %q", log.Description)
+	}
+
+	data, err := sct.signedData(entry)
+	if err != nil {
+		return err
+	}
+	return verifySignature(pub, data, sct.Signature)
+}
+
+// keyDER normalizes a configured public key to bare DER, stripping PEM
+// armor if present, so a PEM-wrapped and a raw-DER config of the same key
+// hash to the same log ID (SHA-256 of the DER SubjectPublicKeyInfo, per RFC
+// 6962 §3.2) and parse identically.
+func keyDER(key []byte) []byte {
+	if block, _ := pem.Decode(key); block != nil {
+		return block.Bytes
+	}
+	return key
+}
+
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+	return x509.ParsePKIXPublicKey(keyDER(key))
+}
+
+func verifySignature(pub crypto.PublicKey, data, sig []byte) error {
+	digest := sha256.Sum256(data)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return errors.New("ECDSA signature verification failed")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+// testLog generates an ECDSA log key and returns the loglist.Log using it
+// (with the key DER-encoded, as a log fetched from a real log list would
+// be) plus the raw private key to sign SCTs/STHs with in tests.
+func testLog(t *testing.T) (loglist.Log, *ecdsa.PrivateKey) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("marshaling public key: %v", err)
+	}
+	return loglist.Log{Description: "Test Log", Key: der}, priv
+}
+
+func signSCT(t *testing.T, priv *ecdsa.PrivateKey, entry Entry, sct SCT) SCT {
+	t.Helper()
+	data, err := sct.signedData(entry)
+	if err != nil {
+		t.Fatalf("computing signed data: %v", err)
+	}
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	sct.Signature = sig
+	return sct
+}
+
+func TestVerifySCT(t *testing.T) {
+	log, priv := testLog(t)
+	entry := Entry{URL: "https://example.com/file", SHA256: []byte("digest"), Timestamp: 12345}
+	logID := sha256.Sum256(log.Key)
+
+	valid := signSCT(t, priv, entry, SCT{LogID: logID[:], Timestamp: entry.Timestamp})
+	if err := VerifySCT(log, entry, valid); err != nil {
+		t.Fatalf("valid SCT rejected: %v", err)
+	}
+
+	t.Run("PEM-wrapped log key", func(t *testing.T) {
+		pemLog := log
+		pemLog.Key = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: log.Key})
+		if err := VerifySCT(pemLog, entry, valid); err != nil {
+			t.Fatalf("valid SCT rejected for a PEM-wrapped log key: %v", err)
+		}
+	})
+
+	t.Run("wrong log ID", func(t *testing.T) {
+		tampered := valid
+		badID := sha256.Sum256([]byte("not the log key"))
+		tampered.LogID = badID[:]
+		if err := VerifySCT(log, entry, tampered); err == nil {
+			t.Error("expected error for a mismatched log ID")
+		}
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		tampered := valid
+		tampered.Signature = append([]byte{}, valid.Signature...)
+		tampered.Signature[0] ^= 0xff
+		if err := VerifySCT(log, entry, tampered); err == nil {
+			t.Error("expected error for a tampered signature")
+		}
+	})
+
+	t.Run("different entry", func(t *testing.T) {
+		other := entry
+		other.URL = "https://example.com/other-file"
+		if err := VerifySCT(log, other, valid); err == nil {
+			t.Error("expected error for an SCT checked against a different entry")
+		}
+	})
+
+	t.Run("different timestamp", func(t *testing.T) {
+		tampered := valid
+		tampered.Timestamp++
+		if err := VerifySCT(log, entry, tampered); err == nil {
+			t.Error("expected error for a mismatched timestamp")
+		}
+	})
+}
+
+func TestKeyDERNormalizesPEM(t *testing.T) {
+	log, _ := testLog(t)
+	wrapped := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: log.Key})
+
+	if string(keyDER(log.Key)) != string(log.Key) {
+		t.Error("keyDER changed an already-bare DER key")
+	}
+	if string(keyDER(wrapped)) != string(log.Key) {
+		t.Error("keyDER did not strip PEM armor down to the same DER bytes")
+	}
+}
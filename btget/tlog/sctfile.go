@@ -0,0 +1,51 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SCTFile is the sidecar `btget submit` writes next to a downloaded file,
+// and `btget verify` reads back: the Entry that was submitted, plus the
+// SCTs each log issued for it, keyed by log URL.
+type SCTFile struct {
+	Entry Entry            `json:"entry"`
+	SCTs  map[string][]SCT `json:"scts"`
+}
+
+// WriteSCTFile writes f to path as indented JSON.
+func WriteSCTFile(path string, f SCTFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling sct file: %w", err)
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// ReadSCTFile reads back an SCTFile previously written by WriteSCTFile.
+func ReadSCTFile(path string) (SCTFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return SCTFile{}, fmt.Errorf("reading sct file: %w", err)
+	}
+	var f SCTFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return SCTFile{}, fmt.Errorf("parsing sct file: %w", err)
+	}
+	return f, nil
+}
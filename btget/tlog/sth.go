@@ -0,0 +1,48 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"encoding/binary"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+// STH is a Signed Tree Head: a log's signed commitment to the root hash of
+// its Merkle tree at a given size, analogous to RFC 6962 §3.5.
+type STH struct {
+	TreeSize  int64  `json:"tree_size"`
+	Timestamp uint64 `json:"timestamp"`
+	RootHash  []byte `json:"sha256_root_hash"`
+	Signature []byte `json:"tree_head_signature"`
+}
+
+// signedData returns the bytes a log signs over to produce an STH.
+func (s STH) signedData() []byte {
+	buf := make([]byte, 24, 24+len(s.RootHash))
+	binary.BigEndian.PutUint64(buf[0:8], s.Timestamp)
+	binary.BigEndian.PutUint64(buf[8:16], uint64(s.TreeSize))
+	binary.BigEndian.PutUint64(buf[16:24], uint64(len(s.RootHash)))
+	return append(buf, s.RootHash...)
+}
+
+// VerifySTH checks sth's signature under log's public key.
+func VerifySTH(log loglist.Log, sth STH) error {
+	pub, err := parsePublicKey(log.Key)
+	if err != nil {
+		return err
+	}
+	return verifySignature(pub, sth.signedData(), sth.Signature)
+}
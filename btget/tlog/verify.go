@@ -0,0 +1,169 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/google/certificate-transparency-go/loglist"
+)
+
+// LogResult is the outcome of checking a single log's inclusion proof for a
+// downloaded URL.
+type LogResult struct {
+	Log loglist.Log
+	Err error
+}
+
+// Verify checks, for every log in logs, that durl/sum was logged and is
+// included in that log's current tree. It fetches the log's entry and SCTs,
+// requires at least one of those SCTs to carry a valid signature by the log
+// (the others are not checked once one verifies), fetches a fresh STH (verifying its
+// signature), recomputes the Merkle root from a get-proof-by-hash audit path
+// and compares it to the STH, checks consistency against a cached previous
+// STH for that log if one is cached in cacheDir, and, if witnesses are
+// given, requires each of them to have cosigned the STH.
+//
+// It returns one LogResult per log, in the same order as logs.
+func Verify(durl string, sum []byte, logs []loglist.Log, witnesses []Witness, cacheDir string) ([]LogResult, error) {
+	cache, err := OpenSTHCache(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening STH cache: %w", err)
+	}
+
+	wantEntry := Entry{URL: durl, SHA256: sum}
+	results := make([]LogResult, len(logs))
+	for i, log := range logs {
+		loggedEntry, scts, err := NewClient(log.URL).GetEntry(durl)
+		if err != nil {
+			results[i] = LogResult{Log: log, Err: fmt.Errorf("fetching entry from %q: %w", log.Description, err)}
+			continue
+		}
+		if string(loggedEntry.SHA256) != string(wantEntry.SHA256) {
+			results[i] = LogResult{Log: log, Err: fmt.Errorf("log %q has a different digest logged for %s", log.Description, durl)}
+			continue
+		}
+		results[i] = LogResult{Log: log, Err: verifyEntry(log, loggedEntry, scts, witnesses, cache, false)}
+	}
+	return results, nil
+}
+
+// VerifyOffline checks entry against each log's current tree using SCTs that
+// are already known (e.g. loaded from a `btget submit` sidecar file), rather
+// than fetching them from the log via get-entry, and trusts a cached STH
+// from a previous run instead of fetching a new one when one is available.
+// It still needs the network for an inclusion proof against that STH's tree
+// size, to fetch a fresh STH for any log with nothing cached yet, and to ask
+// any witnesses to cosign the STH; it is
+// "offline" only in the sense of not needing the file or its SCTs re-fetched.
+func VerifyOffline(entry Entry, sctsByLog map[string][]SCT, logs []loglist.Log, witnesses []Witness, cacheDir string) ([]LogResult, error) {
+	cache, err := OpenSTHCache(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening STH cache: %w", err)
+	}
+
+	results := make([]LogResult, len(logs))
+	for i, log := range logs {
+		scts := sctsByLog[log.URL]
+		if len(scts) == 0 {
+			results[i] = LogResult{Log: log, Err: fmt.Errorf("no SCT for log %q in sct file", log.Description)}
+			continue
+		}
+		results[i] = LogResult{Log: log, Err: verifyEntry(log, entry, scts, witnesses, cache, true)}
+	}
+	return results, nil
+}
+
+// verifyEntry checks entry's SCTs and inclusion proof against log. When
+// preferCachedSTH is set and cache already holds a previously verified STH
+// for log, that STH is trusted directly instead of fetching (and
+// re-verifying the signature of) a new one.
+func verifyEntry(log loglist.Log, entry Entry, scts []SCT, witnesses []Witness, cache *STHCache, preferCachedSTH bool) error {
+	if len(scts) == 0 {
+		return fmt.Errorf("log %q returned no SCTs for %s", log.Description, entry.URL)
+	}
+	client := NewClient(log.URL)
+
+	var sctErr error
+	for _, sct := range scts {
+		if err := VerifySCT(log, entry, sct); err != nil {
+			sctErr = err
+			continue
+		}
+		sctErr = nil
+		break
+	}
+	if sctErr != nil {
+		return fmt.Errorf("no valid SCT from %q: %w", log.Description, sctErr)
+	}
+
+	var sth STH
+	var cached bool
+	if preferCachedSTH {
+		sth, cached = cache.Get(log.URL)
+	}
+	if !cached {
+		var err error
+		sth, err = client.GetSTH()
+		if err != nil {
+			return fmt.Errorf("fetching STH from %q: %w", log.Description, err)
+		}
+		if err := VerifySTH(log, sth); err != nil {
+			return fmt.Errorf("invalid STH signature from %q: %w", log.Description, err)
+		}
+	}
+
+	logID := sha256.Sum256(keyDER(log.Key))
+	for _, w := range witnesses {
+		sig, err := NewWitnessClient(w.URL).Cosign(logID[:], sth)
+		if err != nil {
+			return fmt.Errorf("fetching cosignature from witness %q for %q: %w", w.Description, log.Description, err)
+		}
+		if err := VerifyCosignature(w, logID[:], sth, sig); err != nil {
+			return fmt.Errorf("invalid cosignature from witness %q for %q: %w", w.Description, log.Description, err)
+		}
+	}
+
+	leafHash, err := entry.LeafHash()
+	if err != nil {
+		return err
+	}
+	index, auditPath, err := client.GetProofByHash(leafHash, sth.TreeSize)
+	if err != nil {
+		return fmt.Errorf("fetching inclusion proof from %q: %w", log.Description, err)
+	}
+	if err := VerifyInclusion(leafHash, index, sth.TreeSize, auditPath, sth.RootHash); err != nil {
+		return fmt.Errorf("inclusion proof from %q did not verify: %w", log.Description, err)
+	}
+
+	if prev, ok := cache.Get(log.URL); ok {
+		var proof [][]byte
+		if prev.TreeSize != sth.TreeSize {
+			proof, err = client.GetSTHConsistency(prev.TreeSize, sth.TreeSize)
+			if err != nil {
+				return fmt.Errorf("fetching consistency proof from %q: %w", log.Description, err)
+			}
+		}
+		if err := VerifyConsistency(prev, sth, proof); err != nil {
+			return fmt.Errorf("consistency proof from %q did not verify: %w", log.Description, err)
+		}
+	}
+
+	if err := cache.Put(log.URL, sth); err != nil {
+		return fmt.Errorf("caching STH from %q: %w", log.Description, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright © 2019 The Transparency Log Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WitnessClient talks to a single witness's HTTP API: given a log's STH, the
+// witness either cosigns it (because it's consistent with the last tree
+// head it saw for that log) or refuses.
+type WitnessClient struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewWitnessClient returns a WitnessClient for the witness reachable at
+// baseURL.
+func NewWitnessClient(baseURL string) *WitnessClient {
+	return &WitnessClient{BaseURL: baseURL, HTTP: http.DefaultClient}
+}
+
+// Cosign asks the witness to countersign sth on behalf of the log identified
+// by logID (the SHA-256 of the log's public key, as in SCT.LogID), returning
+// the witness's signature over sth.
+func (w *WitnessClient) Cosign(logID []byte, sth STH) ([]byte, error) {
+	body, err := json.Marshal(struct {
+		LogID []byte `json:"log_id"`
+		STH   STH    `json:"sth"`
+	}{logID, sth})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.HTTP.Post(w.BaseURL+"/cosign", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("POST cosign: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST cosign: unexpected status %s", resp.Status)
+	}
+	var out struct {
+		Signature []byte `json:"signature"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Signature, nil
+}
+
+// VerifyCosignature checks that sig is witness's valid signature over sth on
+// behalf of the log identified by logID. logID is folded into the signed
+// bytes so a cosignature obtained for one log can't be replayed as proof the
+// witness vouches for a different log's STH.
+func VerifyCosignature(witness Witness, logID []byte, sth STH, sig []byte) error {
+	pub, err := parsePublicKey(witness.Key)
+	if err != nil {
+		return fmt.Errorf("parsing public key for witness %q: %w", witness.Description, err)
+	}
+	return verifySignature(pub, cosignedData(logID, sth), sig)
+}
+
+// cosignedData returns the bytes a witness signs to cosign sth for the log
+// identified by logID.
+func cosignedData(logID []byte, sth STH) []byte {
+	return append(append([]byte{}, logID...), sth.signedData()...)
+}